@@ -0,0 +1,54 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveCompletedMovesOnlyCompletedTodos(t *testing.T) {
+	doneFile := filepath.Join(t.TempDir(), "done.json")
+	tl := &TodoList{}
+	tl.todos = []Todo{
+		{ID: 1, Title: "done task", Completed: true},
+		{ID: 2, Title: "active task", Completed: false},
+	}
+
+	if err := tl.ArchiveCompleted(doneFile); err != nil {
+		t.Fatalf("ArchiveCompleted: %v", err)
+	}
+
+	if len(tl.todos) != 1 || tl.todos[0].Title != "active task" {
+		t.Fatalf("active list after archive = %+v", tl.todos)
+	}
+
+	archive := &TodoList{}
+	if err := archive.Load(doneFile); err != nil {
+		t.Fatalf("loading archive: %v", err)
+	}
+	if len(archive.todos) != 1 || archive.todos[0].Title != "done task" {
+		t.Fatalf("archive contents = %+v", archive.todos)
+	}
+}
+
+func TestArchiveCompletedIsCumulative(t *testing.T) {
+	doneFile := filepath.Join(t.TempDir(), "done.json")
+	first := &TodoList{todos: []Todo{{ID: 1, Title: "first", Completed: true}}}
+	if err := first.ArchiveCompleted(doneFile); err != nil {
+		t.Fatalf("first ArchiveCompleted: %v", err)
+	}
+	second := &TodoList{todos: []Todo{{ID: 1, Title: "second", Completed: true}}}
+	if err := second.ArchiveCompleted(doneFile); err != nil {
+		t.Fatalf("second ArchiveCompleted: %v", err)
+	}
+
+	archive := &TodoList{}
+	if err := archive.Load(doneFile); err != nil {
+		t.Fatalf("loading archive: %v", err)
+	}
+	if len(archive.todos) != 2 {
+		t.Fatalf("archive has %d todos, want 2", len(archive.todos))
+	}
+	if archive.todos[0].ID == archive.todos[1].ID {
+		t.Errorf("archived todos share ID %d, want distinct IDs", archive.todos[0].ID)
+	}
+}
@@ -0,0 +1,460 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// icalDateTimeFormat and icalDateFormat are the UTC timestamp and
+// date-only layouts used by iCalendar properties such as CREATED,
+// COMPLETED, LAST-MODIFIED, and DUE.
+const (
+	icalDateTimeFormat = "20060102T150405Z"
+	icalDateFormat     = "20060102"
+)
+
+// CalDAVConfig holds the connection details for a CalDAV server.
+type CalDAVConfig struct {
+	ServerURL    string `json:"server_url"`
+	CalendarPath string `json:"calendar_path"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+}
+
+// loadCalDAVConfig reads CalDAVConfig from configPath (if present) and
+// then overlays CALDAV_SERVER_URL, CALDAV_CALENDAR_PATH, CALDAV_USERNAME,
+// and CALDAV_PASSWORD/CALDAV_TOKEN from the environment, which take
+// precedence over the file.
+func loadCalDAVConfig(configPath string) (CalDAVConfig, error) {
+	var cfg CalDAVConfig
+
+	if data, err := os.ReadFile(configPath); err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("caldav: parsing %s: %w", configPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return cfg, err
+	}
+
+	if v := os.Getenv("CALDAV_SERVER_URL"); v != "" {
+		cfg.ServerURL = v
+	}
+	if v := os.Getenv("CALDAV_CALENDAR_PATH"); v != "" {
+		cfg.CalendarPath = v
+	}
+	if v := os.Getenv("CALDAV_USERNAME"); v != "" {
+		cfg.Username = v
+	}
+	if v := os.Getenv("CALDAV_PASSWORD"); v != "" {
+		cfg.Password = v
+	} else if v := os.Getenv("CALDAV_TOKEN"); v != "" {
+		cfg.Password = v
+	}
+
+	if cfg.ServerURL == "" {
+		return cfg, fmt.Errorf("caldav: no server configured (set CALDAV_SERVER_URL or create %s)", configPath)
+	}
+	return cfg, nil
+}
+
+// CalDAVClient pushes and pulls Todo items to a CalDAV server as
+// iCalendar VTODO objects.
+type CalDAVClient struct {
+	Config     CalDAVConfig
+	HTTPClient *http.Client
+}
+
+// NewCalDAVClient builds a CalDAVClient for cfg with a sane request timeout.
+func NewCalDAVClient(cfg CalDAVConfig) *CalDAVClient {
+	return &CalDAVClient{
+		Config:     cfg,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SyncSummary reports what a Sync call changed.
+type SyncSummary struct {
+	Pushed    int
+	Pulled    int
+	Conflicts int
+}
+
+func (c *CalDAVClient) calendarURL() string {
+	return strings.TrimRight(c.Config.ServerURL, "/") + "/" + strings.TrimLeft(c.Config.CalendarPath, "/")
+}
+
+func (c *CalDAVClient) resolveHref(href string) string {
+	if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+		return href
+	}
+	base, err := url.Parse(c.Config.ServerURL)
+	if err != nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func (c *CalDAVClient) do(req *http.Request) (*http.Response, error) {
+	if c.Config.Username != "" {
+		req.SetBasicAuth(c.Config.Username, c.Config.Password)
+	}
+	return c.HTTPClient.Do(req)
+}
+
+// Push writes each todo to the server as a VTODO resource named after its UID.
+func (c *CalDAVClient) Push(todos []Todo) error {
+	for _, todo := range todos {
+		if todo.UID == "" {
+			todo.UID = newUUID()
+		}
+		resourceURL := strings.TrimRight(c.calendarURL(), "/") + "/" + todo.UID + ".ics"
+
+		req, err := http.NewRequest(http.MethodPut, resourceURL, strings.NewReader(todoToVTODO(todo)))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+
+		resp, err := c.do(req)
+		if err != nil {
+			return fmt.Errorf("caldav: push %s: %w", todo.UID, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("caldav: push %s: server returned %s", todo.UID, resp.Status)
+		}
+	}
+	return nil
+}
+
+// davMultistatus is the minimal WebDAV multistatus shape needed to read
+// back the hrefs of resources in a calendar collection.
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"DAV: multistatus"`
+	Responses []davResponse `xml:"response"`
+}
+
+type davResponse struct {
+	Href string `xml:"href"`
+}
+
+// listResources runs a depth-1 PROPFIND against the calendar collection
+// and returns the hrefs of its .ics members.
+func (c *CalDAVClient) listResources() ([]string, error) {
+	const propfindBody = `<?xml version="1.0" encoding="utf-8" ?><propfind xmlns="DAV:"><prop><getetag/></prop></propfind>`
+
+	req, err := http.NewRequest("PROPFIND", c.calendarURL(), strings.NewReader(propfindBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("caldav: listing calendar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("caldav: listing calendar: server returned %s", resp.Status)
+	}
+
+	var ms davMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("caldav: decoding PROPFIND response: %w", err)
+	}
+
+	var hrefs []string
+	for _, r := range ms.Responses {
+		if strings.HasSuffix(r.Href, ".ics") {
+			hrefs = append(hrefs, r.Href)
+		}
+	}
+	return hrefs, nil
+}
+
+func (c *CalDAVClient) getResource(href string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, c.resolveHref(href), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("caldav: fetching %s: server returned %s", href, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Pull fetches every VTODO resource in the configured calendar and
+// decodes it into a Todo.
+func (c *CalDAVClient) Pull() ([]Todo, error) {
+	hrefs, err := c.listResources()
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []Todo
+	for _, href := range hrefs {
+		body, err := c.getResource(href)
+		if err != nil {
+			return nil, err
+		}
+		todo, err := parseVTODO(body)
+		if err != nil {
+			continue // not a VTODO we understand; skip rather than fail the whole pull
+		}
+		todos = append(todos, todo)
+	}
+	return todos, nil
+}
+
+// remoteStore is the subset of CalDAVClient that the merge logic in Sync
+// needs. It exists so that logic can be exercised with a fake in tests,
+// without a live server.
+type remoteStore interface {
+	Pull() ([]Todo, error)
+	Push(todos []Todo) error
+}
+
+// Sync reconciles local against the CalDAV server by UID: remote-only
+// todos are pulled in, local-only todos are pushed out, and todos present
+// on both sides keep whichever copy was modified most recently.
+func (c *CalDAVClient) Sync(local *TodoList) (SyncSummary, error) {
+	return syncWith(c, local)
+}
+
+func syncWith(remote remoteStore, local *TodoList) (SyncSummary, error) {
+	remoteTodos, err := remote.Pull()
+	if err != nil {
+		return SyncSummary{}, err
+	}
+	remoteByUID := make(map[string]Todo, len(remoteTodos))
+	for _, rt := range remoteTodos {
+		remoteByUID[rt.UID] = rt
+	}
+
+	local.mu.Lock()
+	localIndexByUID := make(map[string]int, len(local.todos))
+	for i, lt := range local.todos {
+		if lt.UID != "" {
+			localIndexByUID[lt.UID] = i
+		}
+	}
+
+	var summary SyncSummary
+	var toPush []Todo
+
+	for uid, rt := range remoteByUID {
+		i, ok := localIndexByUID[uid]
+		if !ok {
+			local.idCounter++
+			rt.ID = local.idCounter
+			local.todos = append(local.todos, rt)
+			summary.Pulled++
+			continue
+		}
+
+		lt := local.todos[i]
+		delete(localIndexByUID, uid)
+		switch {
+		case rt.ModifiedAt.After(lt.ModifiedAt):
+			rt.ID = lt.ID
+			local.todos[i] = rt
+			summary.Pulled++
+			if !lt.ModifiedAt.IsZero() {
+				summary.Conflicts++
+			}
+		case lt.ModifiedAt.After(rt.ModifiedAt):
+			toPush = append(toPush, lt)
+			summary.Pushed++
+			if !rt.ModifiedAt.IsZero() {
+				summary.Conflicts++
+			}
+		}
+	}
+
+	// Whatever's left in localIndexByUID wasn't matched to a remote UID:
+	// it's local-only and needs pushing.
+	for _, i := range localIndexByUID {
+		toPush = append(toPush, local.todos[i])
+		summary.Pushed++
+	}
+
+	// Todos created before sync was ever configured have no UID yet.
+	for i := range local.todos {
+		if local.todos[i].UID == "" {
+			local.todos[i].UID = newUUID()
+			toPush = append(toPush, local.todos[i])
+			summary.Pushed++
+		}
+	}
+
+	if summary.Pulled > 0 || len(toPush) > 0 {
+		local.changed = true
+	}
+	local.mu.Unlock()
+
+	if len(toPush) > 0 {
+		if err := remote.Push(toPush); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// AutoSync periodically reconciles local against the CalDAV server until
+// done is closed, mirroring how TodoList.AutoSave runs alongside it.
+func (t *TodoList) AutoSync(client *CalDAVClient, interval time.Duration, done chan bool, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			summary, err := client.Sync(t)
+			if err != nil {
+				fmt.Println("Error syncing with CalDAV server:", err)
+				continue
+			}
+			if summary.Pushed > 0 || summary.Pulled > 0 {
+				fmt.Printf("CalDAV sync: pushed %d, pulled %d, conflicts %d\n", summary.Pushed, summary.Pulled, summary.Conflicts)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// todoToVTODO renders a Todo as a full iCalendar document containing a
+// single VTODO component.
+func todoToVTODO(t Todo) string {
+	status := "NEEDS-ACTION"
+	if t.Completed {
+		status = "COMPLETED"
+	}
+
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//golang-guessing-game//todo//EN\r\n")
+	b.WriteString("BEGIN:VTODO\r\n")
+	fmt.Fprintf(&b, "UID:%s\r\n", t.UID)
+	fmt.Fprintf(&b, "SUMMARY:%s\r\n", escapeICalText(t.Title))
+	fmt.Fprintf(&b, "STATUS:%s\r\n", status)
+	if !t.CreatedAt.IsZero() {
+		fmt.Fprintf(&b, "CREATED:%s\r\n", t.CreatedAt.UTC().Format(icalDateTimeFormat))
+	}
+	if t.CompletedAt != nil {
+		fmt.Fprintf(&b, "COMPLETED:%s\r\n", t.CompletedAt.UTC().Format(icalDateTimeFormat))
+	}
+	if t.DueDate != nil {
+		fmt.Fprintf(&b, "DUE;VALUE=DATE:%s\r\n", t.DueDate.Format(icalDateFormat))
+	}
+	if !t.ModifiedAt.IsZero() {
+		fmt.Fprintf(&b, "LAST-MODIFIED:%s\r\n", t.ModifiedAt.UTC().Format(icalDateTimeFormat))
+	}
+	b.WriteString("END:VTODO\r\n")
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
+
+// parseVTODO decodes the first VTODO component found in raw into a Todo.
+func parseVTODO(raw string) (Todo, error) {
+	var t Todo
+	inVTODO := false
+
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "BEGIN:VTODO":
+			inVTODO = true
+		case line == "END:VTODO":
+			inVTODO = false
+		case inVTODO:
+			key, value, ok := splitICalLine(line)
+			if !ok {
+				continue
+			}
+			switch key {
+			case "UID":
+				t.UID = value
+			case "SUMMARY":
+				t.Title = unescapeICalText(value)
+			case "STATUS":
+				t.Completed = value == "COMPLETED"
+			case "CREATED":
+				if parsed, err := time.Parse(icalDateTimeFormat, value); err == nil {
+					t.CreatedAt = parsed
+				}
+			case "COMPLETED":
+				if parsed, err := time.Parse(icalDateTimeFormat, value); err == nil {
+					t.CompletedAt = &parsed
+				}
+			case "DUE":
+				if parsed, err := time.Parse(icalDateFormat, value); err == nil {
+					t.DueDate = &parsed
+				}
+			case "LAST-MODIFIED":
+				if parsed, err := time.Parse(icalDateTimeFormat, value); err == nil {
+					t.ModifiedAt = parsed
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return t, err
+	}
+	if t.UID == "" {
+		return t, fmt.Errorf("caldav: VTODO is missing a UID")
+	}
+	return t, nil
+}
+
+// splitICalLine splits an unfolded "KEY;PARAM=value:VALUE" or "KEY:VALUE"
+// iCalendar content line into its bare property name and value.
+func splitICalLine(line string) (key, value string, ok bool) {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return "", "", false
+	}
+	key, value = line[:idx], line[idx+1:]
+	if semi := strings.Index(key, ";"); semi >= 0 {
+		key = key[:semi]
+	}
+	return key, value, true
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\n", `\n`)
+
+func escapeICalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+var icalUnescaper = strings.NewReplacer(`\n`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeICalText(s string) string {
+	return icalUnescaper.Replace(s)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTodoToVTODORoundTrip(t *testing.T) {
+	due := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2024, 2, 20, 9, 30, 0, 0, time.UTC)
+	original := Todo{
+		UID:         "uid-1",
+		Title:       "Call; Mom, then\nbuy \\ milk",
+		Completed:   true,
+		CreatedAt:   time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		CompletedAt: &completedAt,
+		DueDate:     &due,
+		ModifiedAt:  time.Date(2024, 2, 20, 9, 30, 0, 0, time.UTC),
+	}
+
+	got, err := parseVTODO(todoToVTODO(original))
+	if err != nil {
+		t.Fatalf("parseVTODO: %v", err)
+	}
+
+	if got.UID != original.UID {
+		t.Errorf("UID = %q, want %q", got.UID, original.UID)
+	}
+	if got.Title != original.Title {
+		t.Errorf("Title = %q, want %q", got.Title, original.Title)
+	}
+	if !got.Completed {
+		t.Errorf("Completed = false, want true")
+	}
+	if !got.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, original.CreatedAt)
+	}
+	if got.CompletedAt == nil || !got.CompletedAt.Equal(*original.CompletedAt) {
+		t.Errorf("CompletedAt = %v, want %v", got.CompletedAt, original.CompletedAt)
+	}
+	if got.DueDate == nil || !got.DueDate.Equal(*original.DueDate) {
+		t.Errorf("DueDate = %v, want %v", got.DueDate, original.DueDate)
+	}
+	if !got.ModifiedAt.Equal(original.ModifiedAt) {
+		t.Errorf("ModifiedAt = %v, want %v", got.ModifiedAt, original.ModifiedAt)
+	}
+}
+
+func TestEscapeICalTextRoundTrip(t *testing.T) {
+	const raw = `a; b, c\d` + "\ne"
+	escaped := escapeICalText(raw)
+	if escaped == raw {
+		t.Fatalf("escapeICalText(%q) did not change the input", raw)
+	}
+	if got := unescapeICalText(escaped); got != raw {
+		t.Errorf("unescapeICalText(escapeICalText(%q)) = %q, want %q", raw, got, raw)
+	}
+}
+
+// fakeRemote is a remoteStore that keeps its state in memory, so Sync's
+// merge logic can be tested without a live CalDAV server.
+type fakeRemote struct {
+	todos []Todo
+}
+
+func (f *fakeRemote) Pull() ([]Todo, error) {
+	return f.todos, nil
+}
+
+func (f *fakeRemote) Push(todos []Todo) error {
+	byUID := make(map[string]int, len(f.todos))
+	for i, t := range f.todos {
+		byUID[t.UID] = i
+	}
+	for _, t := range todos {
+		if i, ok := byUID[t.UID]; ok {
+			f.todos[i] = t
+			continue
+		}
+		f.todos = append(f.todos, t)
+	}
+	return nil
+}
+
+func TestSyncWithPushesLocalOnlyTodo(t *testing.T) {
+	remote := &fakeRemote{}
+	local := &TodoList{todos: []Todo{{ID: 1, UID: "local-only", Title: "local"}}}
+
+	summary, err := syncWith(remote, local)
+	if err != nil {
+		t.Fatalf("syncWith: %v", err)
+	}
+	if summary.Pushed != 1 || summary.Pulled != 0 {
+		t.Errorf("summary = %+v, want {Pushed:1 Pulled:0}", summary)
+	}
+	if len(remote.todos) != 1 || remote.todos[0].UID != "local-only" {
+		t.Errorf("remote.todos = %+v", remote.todos)
+	}
+}
+
+func TestSyncWithPullsRemoteOnlyTodo(t *testing.T) {
+	remote := &fakeRemote{todos: []Todo{{UID: "remote-only", Title: "remote"}}}
+	local := &TodoList{}
+
+	summary, err := syncWith(remote, local)
+	if err != nil {
+		t.Fatalf("syncWith: %v", err)
+	}
+	if summary.Pulled != 1 || summary.Pushed != 0 {
+		t.Errorf("summary = %+v, want {Pushed:0 Pulled:1}", summary)
+	}
+	if len(local.todos) != 1 || local.todos[0].UID != "remote-only" {
+		t.Errorf("local.todos = %+v", local.todos)
+	}
+}
+
+func TestSyncWithModifiedOnBothSidesKeepsNewerLocal(t *testing.T) {
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+	remote := &fakeRemote{todos: []Todo{{UID: "shared", Title: "remote title", ModifiedAt: older}}}
+	local := &TodoList{todos: []Todo{{ID: 1, UID: "shared", Title: "local title", ModifiedAt: newer}}}
+
+	summary, err := syncWith(remote, local)
+	if err != nil {
+		t.Fatalf("syncWith: %v", err)
+	}
+	if summary.Pushed != 1 || summary.Conflicts != 1 {
+		t.Errorf("summary = %+v, want {Pushed:1 Conflicts:1}", summary)
+	}
+	if local.todos[0].Title != "local title" {
+		t.Errorf("local title = %q, want it kept as %q", local.todos[0].Title, "local title")
+	}
+	if remote.todos[0].Title != "local title" {
+		t.Errorf("remote title = %q, want overwritten with %q", remote.todos[0].Title, "local title")
+	}
+}
+
+func TestSyncWithModifiedOnBothSidesKeepsNewerRemote(t *testing.T) {
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+	remote := &fakeRemote{todos: []Todo{{UID: "shared", Title: "remote title", ModifiedAt: newer}}}
+	local := &TodoList{todos: []Todo{{ID: 1, UID: "shared", Title: "local title", ModifiedAt: older}}}
+
+	summary, err := syncWith(remote, local)
+	if err != nil {
+		t.Fatalf("syncWith: %v", err)
+	}
+	if summary.Pulled != 1 || summary.Conflicts != 1 {
+		t.Errorf("summary = %+v, want {Pulled:1 Conflicts:1}", summary)
+	}
+	if local.todos[0].Title != "remote title" {
+		t.Errorf("local title = %q, want overwritten with %q", local.todos[0].Title, "remote title")
+	}
+	if local.todos[0].ID != 1 {
+		t.Errorf("local.todos[0].ID = %d, want it to keep the local ID 1", local.todos[0].ID)
+	}
+}
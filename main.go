@@ -2,20 +2,56 @@ package main
 
 import (
 	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
+// todoTxtDateFormat is the date layout used throughout the todo.txt format
+// (creation date, completion date, and the due: tag).
+const todoTxtDateFormat = "2006-01-02"
+
+// priorityRe matches a todo.txt priority token such as "(A)".
+var priorityRe = regexp.MustCompile(`^\(([A-Z])\)$`)
+
+// newUUID generates a random (version 4) UUID, used to give each todo a
+// stable identity that survives across CalDAV sync round-trips.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("uuid-gen-error-%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
 // Todo represents a single task with a title, completion status, and creation time.
 type Todo struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Completed bool      `json:"completed"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Completed   bool       `json:"completed"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Priority    string     `json:"priority,omitempty"`
+	Projects    []string   `json:"projects,omitempty"`
+	Contexts    []string   `json:"contexts,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	UID         string     `json:"uid,omitempty"`
+	ModifiedAt  time.Time  `json:"modified_at,omitempty"`
 }
 
 // TodoList is a struct that manages a list of todos and a mutex for thread-safe operations.
@@ -23,58 +59,247 @@ type TodoList struct {
 	todos     []Todo
 	idCounter int
 	mu        sync.Mutex
-	changed   bool // Flag to track if any changes have been made
+	changed   bool   // Flag to track if any changes have been made
+	diskHash  string // sha256 of the file contents as of the last successful load/save
+
+	// AutoArchive, when set, makes UpdateTodo move a todo straight into
+	// ArchiveFilename as soon as it's marked completed.
+	AutoArchive     bool
+	ArchiveFilename string
 }
 
+// ErrListChanged is returned by SaveToFile/SaveToTodoTxt when the on-disk
+// file has been modified since it was last loaded or saved, so that an
+// automatic save doesn't silently clobber someone else's edits.
+var ErrListChanged = errors.New("todo list file was changed on disk since it was last loaded or saved")
+
 // CreateTodo adds a new todo to the list.
 func (t *TodoList) CreateTodo(title string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.idCounter++
+	now := time.Now()
 	newTodo := Todo{
-		ID:        t.idCounter,
-		Title:     title,
-		Completed: false,
-		CreatedAt: time.Now(),
+		ID:         t.idCounter,
+		Title:      title,
+		Completed:  false,
+		CreatedAt:  now,
+		UID:        newUUID(),
+		ModifiedAt: now,
 	}
 	t.todos = append(t.todos, newTodo)
 	t.changed = true
 	fmt.Println("To-Do added successfully.")
 }
 
-// ListTodos prints all todos in the list.
-func (t *TodoList) ListTodos() {
+// ListTodos prints the todos in the list, optionally narrowed to those
+// tagged with the given project (without the leading "+") or context
+// (without the leading "@"), and optionally to completed todos only. Pass
+// an empty string to skip a filter.
+func (t *TodoList) ListTodos(projectFilter, contextFilter string, doneOnly bool, format string) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
-	if (len(t.todos)) == 0 {
+	todos := t.filterTodosLocked(projectFilter, contextFilter, doneOnly)
+	t.mu.Unlock()
+	sortTodosByPriority(todos)
+
+	renderer, err := resolveRenderer(format)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	_, isJSON := renderer.(JSONRenderer)
+
+	if len(todos) == 0 && !isJSON {
 		fmt.Println("No To-Dos found.")
 		return
 	}
-	fmt.Println("\nTo-Do List:")
+	if len(todos) > 0 && !isJSON {
+		fmt.Println("\nTo-Do List:")
+	}
+
+	output, err := renderer.Render(todos)
+	if err != nil {
+		fmt.Println("Error rendering todos:", err)
+		return
+	}
+	fmt.Print(output)
+}
+
+// filterTodosLocked returns the todos matching projectFilter/contextFilter,
+// and, if doneOnly is set, only those marked completed. Callers must
+// already hold t.mu.
+func (t *TodoList) filterTodosLocked(projectFilter, contextFilter string, doneOnly bool) []Todo {
+	var filtered []Todo
 	for _, todo := range t.todos {
-		status := "Incomplete"
+		if projectFilter != "" && !containsFold(todo.Projects, projectFilter) {
+			continue
+		}
+		if contextFilter != "" && !containsFold(todo.Contexts, contextFilter) {
+			continue
+		}
+		if doneOnly && !todo.Completed {
+			continue
+		}
+		filtered = append(filtered, todo)
+	}
+	return filtered
+}
+
+// maxTodoID returns the highest ID among todos, or 0 if it's empty. It is
+// used to reseed idCounter after loading a list from disk.
+func maxTodoID(todos []Todo) int {
+	max := 0
+	for _, todo := range todos {
+		if todo.ID > max {
+			max = todo.ID
+		}
+	}
+	return max
+}
+
+// containsFold reports whether values contains s, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortTodosByPriority sorts todos in place, A before Z and priority-less
+// todos last; ties keep their original relative order.
+func sortTodosByPriority(todos []Todo) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		pi, pj := todos[i].Priority, todos[j].Priority
+		if pi == "" {
+			return false
+		}
+		if pj == "" {
+			return true
+		}
+		return pi < pj
+	})
+}
+
+// statusLineOutput is the JSON payload emitted by StatusLine, shaped for
+// i3status/waybar/polybar-style "custom script" blocks.
+type statusLineOutput struct {
+	Icon  string `json:"icon"`
+	State string `json:"state"`
+	Text  string `json:"text"`
+}
+
+// statusDueSoonWindow is how far in the future a due date still counts as
+// "due soon" for the Warning state.
+const statusDueSoonWindow = 24 * time.Hour
+
+// parseFilterToken turns a filter token such as "+home" or "@errands" into
+// the project/context filter ListTodos understands, plus a short label for
+// display. An empty filter matches everything and labels as "tasks".
+func parseFilterToken(filter string) (project, context, label string) {
+	switch {
+	case strings.HasPrefix(filter, "+") && len(filter) > 1:
+		name := filter[1:]
+		return name, "", name
+	case strings.HasPrefix(filter, "@") && len(filter) > 1:
+		name := filter[1:]
+		return "", name, name
+	case filter == "":
+		return "", "", "tasks"
+	default:
+		return "", "", filter
+	}
+}
+
+// StatusLine reports a compact, single-line JSON summary of the
+// (optionally filtered) list, suitable for polling from a status bar. The
+// state is "Warning" whenever an unfinished todo is overdue or due within
+// statusDueSoonWindow, and "Good" otherwise.
+func (t *TodoList) StatusLine(filter string) string {
+	project, context, label := parseFilterToken(filter)
+
+	t.mu.Lock()
+	todos := t.filterTodosLocked(project, context, false)
+	t.mu.Unlock()
+
+	total := len(todos)
+	done := 0
+	warning := false
+	deadline := time.Now().Add(statusDueSoonWindow)
+	for _, todo := range todos {
 		if todo.Completed {
-			status = "Completed"
+			done++
+			continue
 		}
-		fmt.Printf("ID: %d | Title: %s | Status: %s | Created At: %s\n", todo.ID, todo.Title, status, todo.CreatedAt.Format(time.RFC822))
+		if todo.DueDate != nil && todo.DueDate.Before(deadline) {
+			warning = true
+		}
+	}
+
+	state := "Good"
+	if warning {
+		state = "Warning"
+	}
+
+	data, err := json.Marshal(statusLineOutput{
+		Icon:  "tasks",
+		State: state,
+		Text:  fmt.Sprintf("%s: %d/%d", label, done, total),
+	})
+	if err != nil {
+		return fmt.Sprintf(`{"icon":"tasks","state":"Critical","text":"error: %s"}`, err)
 	}
+	return string(data)
 }
 
-// UpdateTodo allows updating a todo's title and completion status.
-func (t *TodoList) UpdateTodo(id int, newTitle string, completed bool) {
+// UpdateTodo updates a todo's title and/or completion status. A nil
+// completed leaves the completion status untouched, so callers that only
+// want to change the title don't have to know or repeat its current value.
+func (t *TodoList) UpdateTodo(id int, newTitle string, completed *bool) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
+	var justArchived *Todo
 	for i, todo := range t.todos {
-		if todo.ID == id {
-			if newTitle != "" {
-				t.todos[i].Title = newTitle
+		if todo.ID != id {
+			continue
+		}
+		wasCompleted := t.todos[i].Completed
+		fieldChanged := false
+
+		if newTitle != "" && newTitle != t.todos[i].Title {
+			t.todos[i].Title = newTitle
+			fieldChanged = true
+		}
+		if completed != nil && *completed != wasCompleted {
+			t.todos[i].Completed = *completed
+			fieldChanged = true
+		}
+
+		if fieldChanged {
+			now := time.Now()
+			t.todos[i].ModifiedAt = now
+			if completed != nil && *completed && !wasCompleted {
+				t.todos[i].CompletedAt = &now
 			}
-			t.todos[i].Completed = completed
 			t.changed = true
-			fmt.Println("To-Do updated successfully.")
-			return
 		}
+
+		if t.AutoArchive && completed != nil && *completed && !wasCompleted {
+			archived := t.todos[i]
+			t.todos = append(t.todos[:i], t.todos[i+1:]...)
+			justArchived = &archived
+		}
+
+		t.mu.Unlock()
+		fmt.Println("To-Do updated successfully.")
+		if justArchived != nil {
+			if err := appendTodosToFile(t.ArchiveFilename, []Todo{*justArchived}); err != nil {
+				fmt.Println("Error archiving completed to-do:", err)
+			}
+		}
+		return
 	}
+	t.mu.Unlock()
 	fmt.Println("To-Do not found.")
 }
 
@@ -93,10 +318,48 @@ func (t *TodoList) DeleteTodo(id int) {
 	fmt.Println("To-Do not found.")
 }
 
-// SaveToFile saves the todos to a file in JSON format.
-func (t *TodoList) SaveToFile(filename string) error {
+// fileHash returns the hex sha256 of filename's contents, or "" if the
+// file does not exist yet.
+func fileHash(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// diskListChanged reports whether filename's contents differ from what
+// they were the last time this list was loaded from or saved to it.
+// Callers must already hold t.mu.
+func (t *TodoList) diskListChanged(filename string) (bool, error) {
+	hash, err := fileHash(filename)
+	if err != nil {
+		return false, err
+	}
+	return hash != t.diskHash, nil
+}
+
+// SaveToFile saves the todos to a file in JSON format. Unless force is
+// set, it refuses to overwrite a file that was modified on disk since the
+// last load or save, returning ErrListChanged.
+func (t *TodoList) SaveToFile(filename string, force bool) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+
+	if !force {
+		changed, err := t.diskListChanged(filename)
+		if err != nil {
+			return err
+		}
+		if changed {
+			return ErrListChanged
+		}
+	}
+
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -110,6 +373,12 @@ func (t *TodoList) SaveToFile(filename string) error {
 	}
 	fmt.Println("To-Do list saved to file.")
 	t.changed = false // Reset the changed flag after saving
+
+	hash, err := fileHash(filename)
+	if err != nil {
+		return err
+	}
+	t.diskHash = hash
 	return nil
 }
 
@@ -128,10 +397,283 @@ func (t *TodoList) LoadFromFile(filename string) error {
 	if err != nil {
 		return err
 	}
+	t.idCounter = maxTodoID(t.todos)
+	fmt.Println("To-Do list loaded from file.")
+
+	hash, err := fileHash(filename)
+	if err != nil {
+		return err
+	}
+	t.diskHash = hash
+	return nil
+}
+
+// LoadFromTodoTxt loads todos from a todo.txt-formatted file, assigning a
+// fresh sequential ID to each line in the order it appears.
+func (t *TodoList) LoadFromTodoTxt(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var todos []Todo
+	idCounter := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		todo, err := parseTodoTxtLine(line)
+		if err != nil {
+			return fmt.Errorf("todo.txt line %q: %w", line, err)
+		}
+		idCounter++
+		todo.ID = idCounter
+		todos = append(todos, todo)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	hash, err := fileHash(filename)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.todos = todos
+	t.idCounter = idCounter
+	t.diskHash = hash
 	fmt.Println("To-Do list loaded from file.")
 	return nil
 }
 
+// SaveToTodoTxt writes the list to filename in todo.txt format, one task
+// per line. Unless force is set, it refuses to overwrite a file that was
+// modified on disk since the last load or save, returning ErrListChanged.
+func (t *TodoList) SaveToTodoTxt(filename string, force bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !force {
+		changed, err := t.diskListChanged(filename)
+		if err != nil {
+			return err
+		}
+		if changed {
+			return ErrListChanged
+		}
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, todo := range t.todos {
+		if _, err := fmt.Fprintln(writer, formatTodoTxtLine(todo)); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	fmt.Println("To-Do list saved to file.")
+	t.changed = false
+
+	hash, err := fileHash(filename)
+	if err != nil {
+		return err
+	}
+	t.diskHash = hash
+	return nil
+}
+
+// ExportJSON writes the list to w as indented JSON, without touching the
+// on-disk file or the changed flag.
+func (t *TodoList) ExportJSON(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(t.todos)
+}
+
+// ExportTodoTxt writes the list to w in todo.txt format, one task per line,
+// without touching the on-disk file or the changed flag.
+func (t *TodoList) ExportTodoTxt(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, todo := range t.todos {
+		if _, err := fmt.Fprintln(w, formatTodoTxtLine(todo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads todos from filename, picking the todo.txt parser for a ".txt"
+// extension and the JSON decoder otherwise.
+func (t *TodoList) Load(filename string) error {
+	if strings.HasSuffix(filename, ".txt") {
+		return t.LoadFromTodoTxt(filename)
+	}
+	return t.LoadFromFile(filename)
+}
+
+// Save writes todos to filename, picking the todo.txt format for a ".txt"
+// extension and JSON otherwise. Unless force is set, it refuses to
+// overwrite a file that changed on disk since the last load or save.
+func (t *TodoList) Save(filename string, force bool) error {
+	if strings.HasSuffix(filename, ".txt") {
+		return t.SaveToTodoTxt(filename, force)
+	}
+	return t.SaveToFile(filename, force)
+}
+
+// archiveFilenameFor returns the done-file that pairs with filename,
+// keeping it in the same format (todo.txt vs JSON).
+func archiveFilenameFor(filename string) string {
+	if strings.HasSuffix(filename, ".txt") {
+		return "done.txt"
+	}
+	return "done.json"
+}
+
+// appendTodosToFile appends todos to doneFilename, assigning them fresh
+// IDs that continue on from whatever is already archived there.
+func appendTodosToFile(doneFilename string, todos []Todo) error {
+	archive := &TodoList{}
+	if err := archive.Load(doneFilename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	archive.mu.Lock()
+	for _, todo := range todos {
+		archive.idCounter++
+		todo.ID = archive.idCounter
+		archive.todos = append(archive.todos, todo)
+	}
+	archive.mu.Unlock()
+
+	return archive.Save(doneFilename, false)
+}
+
+// ArchiveCompleted moves every completed todo out of the active list and
+// appends it to doneFilename, keeping the working list focused on what's
+// still outstanding.
+func (t *TodoList) ArchiveCompleted(doneFilename string) error {
+	t.mu.Lock()
+	var archived, remaining []Todo
+	for _, todo := range t.todos {
+		if todo.Completed {
+			archived = append(archived, todo)
+		} else {
+			remaining = append(remaining, todo)
+		}
+	}
+	if len(archived) == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	t.todos = remaining
+	t.changed = true
+	t.mu.Unlock()
+
+	return appendTodosToFile(doneFilename, archived)
+}
+
+// parseTodoTxtLine parses a single todo.txt line, such as
+// "(A) 2024-01-10 Buy milk +home @errands due:2024-01-15", into a Todo.
+func parseTodoTxtLine(line string) (Todo, error) {
+	fields := strings.Fields(line)
+	idx := 0
+	todo := Todo{}
+
+	if idx < len(fields) && fields[idx] == "x" {
+		todo.Completed = true
+		idx++
+		if idx < len(fields) {
+			if d, err := time.Parse(todoTxtDateFormat, fields[idx]); err == nil {
+				todo.CompletedAt = &d
+				idx++
+			}
+		}
+	}
+
+	if idx < len(fields) {
+		if m := priorityRe.FindStringSubmatch(fields[idx]); m != nil {
+			todo.Priority = m[1]
+			idx++
+		}
+	}
+
+	if idx < len(fields) {
+		if d, err := time.Parse(todoTxtDateFormat, fields[idx]); err == nil {
+			todo.CreatedAt = d
+			idx++
+		}
+	}
+
+	var titleWords []string
+	for _, word := range fields[idx:] {
+		switch {
+		case len(word) > 1 && strings.HasPrefix(word, "+"):
+			todo.Projects = append(todo.Projects, strings.TrimPrefix(word, "+"))
+		case len(word) > 1 && strings.HasPrefix(word, "@"):
+			todo.Contexts = append(todo.Contexts, strings.TrimPrefix(word, "@"))
+		case strings.HasPrefix(word, "due:"):
+			if d, err := time.Parse(todoTxtDateFormat, strings.TrimPrefix(word, "due:")); err == nil {
+				todo.DueDate = &d
+			} else {
+				titleWords = append(titleWords, word)
+			}
+		default:
+			titleWords = append(titleWords, word)
+		}
+	}
+	todo.Title = strings.Join(titleWords, " ")
+
+	return todo, nil
+}
+
+// formatTodoTxtLine renders a Todo back into a todo.txt line.
+func formatTodoTxtLine(todo Todo) string {
+	var parts []string
+
+	if todo.Completed {
+		parts = append(parts, "x")
+		if todo.CompletedAt != nil {
+			parts = append(parts, todo.CompletedAt.Format(todoTxtDateFormat))
+		}
+	}
+	if todo.Priority != "" {
+		parts = append(parts, fmt.Sprintf("(%s)", todo.Priority))
+	}
+	if !todo.CreatedAt.IsZero() {
+		parts = append(parts, todo.CreatedAt.Format(todoTxtDateFormat))
+	}
+
+	parts = append(parts, todo.Title)
+
+	for _, project := range todo.Projects {
+		parts = append(parts, "+"+project)
+	}
+	for _, context := range todo.Contexts {
+		parts = append(parts, "@"+context)
+	}
+	if todo.DueDate != nil {
+		parts = append(parts, "due:"+todo.DueDate.Format(todoTxtDateFormat))
+	}
+
+	return strings.Join(parts, " ")
+}
+
 // AutoSave periodically saves the todos to a file if there are changes.
 func (t *TodoList) AutoSave(filename string, interval time.Duration, done chan bool, wg *sync.WaitGroup) {
 	defer wg.Done()
@@ -147,8 +689,10 @@ func (t *TodoList) AutoSave(filename string, interval time.Duration, done chan b
 			t.mu.Unlock()
 
 			if shouldSave {
-				err := t.SaveToFile(filename)
-				if err != nil {
+				err := t.Save(filename, false)
+				if errors.Is(err, ErrListChanged) {
+					fmt.Println("Auto-save skipped: the file changed on disk. Use 'reload' or 'save --force' to resolve.")
+				} else if err != nil {
 					fmt.Println("Error saving file:", err)
 				}
 			}
@@ -159,16 +703,262 @@ func (t *TodoList) AutoSave(filename string, interval time.Duration, done chan b
 	}
 }
 
+// resolveFilename picks the list file to use: an explicit --file/--data
+// flag wins, then $TODO_FILE, then the todos.json default. Passing a
+// ".txt" path switches the whole program over to reading and writing
+// todo.txt instead of JSON, since Load/Save dispatch on the extension.
+func resolveFilename(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if v := os.Getenv("TODO_FILE"); v != "" {
+		return v
+	}
+	return "todos.json"
+}
+
+// extractFileFlag pulls a --file/--data (or --file=.../--data=...) value
+// out of args, wherever it appears, and returns it along with the
+// remaining arguments so the rest of main's parsing is unaffected.
+func extractFileFlag(args []string) (file string, rest []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--file" || arg == "--data":
+			if i+1 >= len(args) {
+				continue
+			}
+			rest = append(append([]string{}, args[:i]...), args[i+2:]...)
+			return args[i+1], rest
+		case strings.HasPrefix(arg, "--file="):
+			rest = append(append([]string{}, args[:i]...), args[i+1:]...)
+			return strings.TrimPrefix(arg, "--file="), rest
+		case strings.HasPrefix(arg, "--data="):
+			rest = append(append([]string{}, args[:i]...), args[i+1:]...)
+			return strings.TrimPrefix(arg, "--data="), rest
+		}
+	}
+	return "", args
+}
+
 func main() {
 	todoList := &TodoList{}
-	filename := "todos.json"
+	fileOverride, args := extractFileFlag(os.Args[1:])
+	filename := resolveFilename(fileOverride)
+	todoList.ArchiveFilename = archiveFilenameFor(filename)
+	todoList.AutoArchive = os.Getenv("TODO_AUTO_ARCHIVE") != ""
 
 	// Load from file at the start
-	err := todoList.LoadFromFile(filename)
+	err := todoList.Load(filename)
 	if err != nil && !os.IsNotExist(err) {
 		fmt.Println("Error loading file:", err)
 	}
 
+	if len(args) > 0 {
+		switch args[0] {
+		case "--interactive":
+			runInteractive(todoList, filename)
+			return
+		case "--editor":
+			if err := runEditor(todoList, filename); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			return
+		default:
+			if err := runCLI(todoList, filename, args); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	runInteractive(todoList, filename)
+}
+
+// runEditor flushes the current list to filename, opens it in $EDITOR,
+// and reloads it once the editor exits so concurrent edits made by hand
+// are picked back up.
+func runEditor(todoList *TodoList, filename string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		return fmt.Errorf("editor: $EDITOR is not set")
+	}
+
+	if err := todoList.Save(filename, true); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(editor, filename)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor: %w", err)
+	}
+
+	return todoList.Load(filename)
+}
+
+// runCLI dispatches a single non-interactive subcommand (add, list,
+// complete, update, delete, export) and, for commands that change the
+// list, persists the result to filename before returning.
+func runCLI(todoList *TodoList, filename string, args []string) error {
+	cmd := args[0]
+	rest := args[1:]
+	mutates := true
+
+	switch cmd {
+	case "add":
+		fs := flag.NewFlagSet("add", flag.ExitOnError)
+		fs.Parse(rest)
+		title := strings.Join(fs.Args(), " ")
+		if title == "" {
+			return fmt.Errorf("add: a title is required")
+		}
+		todoList.CreateTodo(title)
+
+	case "list":
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		project := fs.String("project", "", "only show todos tagged with this project")
+		context := fs.String("context", "", "only show todos tagged with this context")
+		doneOnly := fs.Bool("done", false, "show archived (completed) todos instead of the active list")
+		format := fs.String("format", "", "render format: table, plain, or json (defaults to table on a terminal, plain otherwise)")
+		fs.Parse(rest)
+		mutates = false
+
+		if *doneOnly {
+			archive := &TodoList{}
+			doneFilename := archiveFilenameFor(filename)
+			if err := archive.Load(doneFilename); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			archive.ListTodos(*project, *context, false, *format)
+			return nil
+		}
+		todoList.ListTodos(*project, *context, false, *format)
+
+	case "archive":
+		mutates = true
+		if err := todoList.ArchiveCompleted(archiveFilenameFor(filename)); err != nil {
+			return err
+		}
+
+	case "sync":
+		fs := flag.NewFlagSet("sync", flag.ExitOnError)
+		config := fs.String("config", ".caldav.json", "path to a CalDAV config file (overlaid by CALDAV_* env vars)")
+		fs.Parse(rest)
+		cfg, err := loadCalDAVConfig(*config)
+		if err != nil {
+			return err
+		}
+		summary, err := NewCalDAVClient(cfg).Sync(todoList)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("CalDAV sync: pushed %d, pulled %d, conflicts %d\n", summary.Pushed, summary.Pulled, summary.Conflicts)
+
+	case "status":
+		fs := flag.NewFlagSet("status", flag.ExitOnError)
+		filter := fs.String("filter", "", "scope the status line to a project (+name) or context (@name)")
+		fs.Parse(rest)
+		mutates = false
+		fmt.Println(todoList.StatusLine(*filter))
+
+	case "complete":
+		if len(rest) != 1 {
+			return fmt.Errorf("complete: expected a single todo ID")
+		}
+		id, err := strconv.Atoi(rest[0])
+		if err != nil {
+			return fmt.Errorf("complete: invalid ID %q", rest[0])
+		}
+		done := true
+		todoList.UpdateTodo(id, "", &done)
+
+	case "update":
+		fs := flag.NewFlagSet("update", flag.ExitOnError)
+		title := fs.String("title", "", "new title (leave empty to keep the current title)")
+		completed := fs.Bool("completed", false, "mark the todo as completed (omit to leave completion status unchanged)")
+		fs.Parse(rest)
+		if fs.NArg() != 1 {
+			return fmt.Errorf("update: expected a single todo ID")
+		}
+		id, err := strconv.Atoi(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("update: invalid ID %q", fs.Arg(0))
+		}
+
+		var completedArg *bool
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "completed" {
+				v := *completed
+				completedArg = &v
+			}
+		})
+		todoList.UpdateTodo(id, *title, completedArg)
+
+	case "delete":
+		if len(rest) != 1 {
+			return fmt.Errorf("delete: expected a single todo ID")
+		}
+		id, err := strconv.Atoi(rest[0])
+		if err != nil {
+			return fmt.Errorf("delete: invalid ID %q", rest[0])
+		}
+		todoList.DeleteTodo(id)
+
+	case "reload":
+		mutates = false
+		return todoList.Load(filename)
+
+	case "save":
+		fs := flag.NewFlagSet("save", flag.ExitOnError)
+		force := fs.Bool("force", false, "overwrite the file even if it changed on disk")
+		fs.Parse(rest)
+		mutates = false
+		return todoList.Save(filename, *force)
+
+	case "export":
+		fs := flag.NewFlagSet("export", flag.ExitOnError)
+		format := fs.String("format", "json", "export format: json or todotxt")
+		output := fs.String("output", "", "output file (defaults to stdout)")
+		fs.Parse(rest)
+		mutates = false
+
+		w := io.Writer(os.Stdout)
+		if *output != "" {
+			file, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+
+		switch *format {
+		case "json":
+			return todoList.ExportJSON(w)
+		case "todotxt":
+			return todoList.ExportTodoTxt(w)
+		default:
+			return fmt.Errorf("export: unknown format %q", *format)
+		}
+
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+
+	if mutates {
+		return todoList.Save(filename, false)
+	}
+	return nil
+}
+
+// runInteractive runs the blocking stdin menu, auto-saving in the
+// background until the user chooses to exit.
+func runInteractive(todoList *TodoList, filename string) {
 	// WaitGroup to wait for auto-save to finish
 	var wg sync.WaitGroup
 
@@ -177,6 +967,12 @@ func main() {
 	wg.Add(1)
 	go todoList.AutoSave(filename, 10*time.Second, done, &wg)
 
+	// If a CalDAV server is configured, also sync to it periodically.
+	if cfg, err := loadCalDAVConfig(".caldav.json"); err == nil {
+		wg.Add(1)
+		go todoList.AutoSync(NewCalDAVClient(cfg), time.Minute, done, &wg)
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Println("Welcome to the Enhanced To-Do Application with Auto-Save!")
 	fmt.Println("----------------------------------------------------------")
@@ -207,7 +1003,7 @@ func main() {
 			}
 		case "2":
 			fmt.Println("\n📋 VIEW ALL TO-DOS")
-			todoList.ListTodos()
+			todoList.ListTodos("", "", false, "")
 		case "3":
 			fmt.Println("\n✏️ UPDATE A TO-DO")
 			fmt.Print("Enter the ID of the to-do to update: ")
@@ -229,7 +1025,7 @@ func main() {
 			completedStr = strings.TrimSpace(completedStr)
 			completed := strings.ToLower(completedStr) == "yes"
 
-			todoList.UpdateTodo(id, newTitle, completed)
+			todoList.UpdateTodo(id, newTitle, &completed)
 			fmt.Println("✅ To-Do updated successfully!")
 		case "4":
 			fmt.Println("\n🗑️ DELETE A TO-DO")
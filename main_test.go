@@ -0,0 +1,120 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTodoTxtLineFull(t *testing.T) {
+	todo, err := parseTodoTxtLine("(A) 2024-01-10 Buy milk +home @errands due:2024-01-15")
+	if err != nil {
+		t.Fatalf("parseTodoTxtLine: %v", err)
+	}
+	if todo.Priority != "A" {
+		t.Errorf("Priority = %q, want %q", todo.Priority, "A")
+	}
+	if todo.Title != "Buy milk" {
+		t.Errorf("Title = %q, want %q", todo.Title, "Buy milk")
+	}
+	if len(todo.Projects) != 1 || todo.Projects[0] != "home" {
+		t.Errorf("Projects = %v, want [home]", todo.Projects)
+	}
+	if len(todo.Contexts) != 1 || todo.Contexts[0] != "errands" {
+		t.Errorf("Contexts = %v, want [errands]", todo.Contexts)
+	}
+	if todo.CreatedAt.Format(todoTxtDateFormat) != "2024-01-10" {
+		t.Errorf("CreatedAt = %v, want 2024-01-10", todo.CreatedAt)
+	}
+	if todo.DueDate == nil || todo.DueDate.Format(todoTxtDateFormat) != "2024-01-15" {
+		t.Errorf("DueDate = %v, want 2024-01-15", todo.DueDate)
+	}
+	if todo.Completed {
+		t.Errorf("Completed = true, want false")
+	}
+}
+
+func TestParseTodoTxtLineCompletedWithXPrefix(t *testing.T) {
+	todo, err := parseTodoTxtLine("x 2024-01-12 2024-01-10 Walk the dog @errands")
+	if err != nil {
+		t.Fatalf("parseTodoTxtLine: %v", err)
+	}
+	if !todo.Completed {
+		t.Fatalf("Completed = false, want true")
+	}
+	if todo.CompletedAt == nil || todo.CompletedAt.Format(todoTxtDateFormat) != "2024-01-12" {
+		t.Errorf("CompletedAt = %v, want 2024-01-12", todo.CompletedAt)
+	}
+	if todo.CreatedAt.Format(todoTxtDateFormat) != "2024-01-10" {
+		t.Errorf("CreatedAt = %v, want 2024-01-10", todo.CreatedAt)
+	}
+	if todo.Title != "Walk the dog" {
+		t.Errorf("Title = %q, want %q", todo.Title, "Walk the dog")
+	}
+}
+
+func TestParseTodoTxtLineMissingCreationDate(t *testing.T) {
+	todo, err := parseTodoTxtLine("Buy milk +home @errands")
+	if err != nil {
+		t.Fatalf("parseTodoTxtLine: %v", err)
+	}
+	if !todo.CreatedAt.IsZero() {
+		t.Errorf("CreatedAt = %v, want zero value", todo.CreatedAt)
+	}
+	if todo.Title != "Buy milk" {
+		t.Errorf("Title = %q, want %q", todo.Title, "Buy milk")
+	}
+}
+
+func TestParseTodoTxtLineKeyValueTag(t *testing.T) {
+	todo, err := parseTodoTxtLine("Renew passport due:2024-03-01 someother:tag")
+	if err != nil {
+		t.Fatalf("parseTodoTxtLine: %v", err)
+	}
+	if todo.DueDate == nil || todo.DueDate.Format(todoTxtDateFormat) != "2024-03-01" {
+		t.Errorf("DueDate = %v, want 2024-03-01", todo.DueDate)
+	}
+	// An unrecognized key:value tag is kept as plain text in the title.
+	if todo.Title != "Renew passport someother:tag" {
+		t.Errorf("Title = %q, want %q", todo.Title, "Renew passport someother:tag")
+	}
+}
+
+func TestLoadSaveTodoTxtRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	original := &TodoList{}
+	original.todos = []Todo{
+		{ID: 1, Title: "Buy milk", Priority: "A", Projects: []string{"home"}, Contexts: []string{"errands"}},
+		{ID: 2, Title: "Walk the dog", Completed: true},
+	}
+
+	if err := original.SaveToTodoTxt(path, true); err != nil {
+		t.Fatalf("SaveToTodoTxt: %v", err)
+	}
+
+	reloaded := &TodoList{}
+	if err := reloaded.LoadFromTodoTxt(path); err != nil {
+		t.Fatalf("LoadFromTodoTxt: %v", err)
+	}
+	if len(reloaded.todos) != 2 {
+		t.Fatalf("got %d todos, want 2", len(reloaded.todos))
+	}
+	if reloaded.todos[0].Title != "Buy milk" || reloaded.todos[0].Priority != "A" {
+		t.Errorf("todos[0] = %+v", reloaded.todos[0])
+	}
+	if !reloaded.todos[1].Completed {
+		t.Errorf("todos[1].Completed = false, want true")
+	}
+}
+
+// contains reports whether needle is a substring of haystack. It's used by
+// tests across this package that assert on fragments of JSON output.
+func contains(haystack, needle string) bool {
+	return len(haystack) >= len(needle) && (func() bool {
+		for i := 0; i+len(needle) <= len(haystack); i++ {
+			if haystack[i:i+len(needle)] == needle {
+				return true
+			}
+		}
+		return false
+	})()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+	ansiGreen  = "\033[32m"
+	ansiDim    = "\033[2m"
+)
+
+// lowPriorityThreshold is the first priority letter (exclusive of A-C)
+// that's dim-rendered as "low priority" in the table view.
+const lowPriorityThreshold = "C"
+
+// Renderer turns a slice of todos into the text that list/export prints.
+type Renderer interface {
+	Render(todos []Todo) (string, error)
+}
+
+// JSONRenderer renders todos as indented JSON, for piping into other tools.
+type JSONRenderer struct{}
+
+func (JSONRenderer) Render(todos []Todo) (string, error) {
+	if todos == nil {
+		todos = []Todo{} // marshal to "[]", not the JSON null a nil slice produces
+	}
+	data, err := json.MarshalIndent(todos, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// PlainRenderer renders one unaligned, uncolored line per todo. It's the
+// format used when stdout isn't a terminal.
+type PlainRenderer struct{}
+
+func (PlainRenderer) Render(todos []Todo) (string, error) {
+	var b strings.Builder
+	for _, todo := range todos {
+		status := "Incomplete"
+		if todo.Completed {
+			status = "Completed"
+		}
+		fmt.Fprintf(&b, "ID: %d | Title: %s | Status: %s | Created At: %s\n",
+			todo.ID, todo.Title, status, todo.CreatedAt.Format(time.RFC822))
+	}
+	return b.String(), nil
+}
+
+// TableRenderer renders todos as an aligned table (ID, Priority, Title,
+// tags, Due, Status, Created), optionally colorizing each row: red for
+// overdue, yellow for due today, green for completed, dim for low priority.
+type TableRenderer struct {
+	Color bool
+}
+
+func (r TableRenderer) Render(todos []Todo) (string, error) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tPriority\tTitle\tTags\tDue\tStatus\tCreated")
+	for _, todo := range todos {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			todo.ID, displayPriority(todo), todo.Title, displayTags(todo),
+			displayDue(todo), displayStatus(todo), todo.CreatedAt.Format(todoTxtDateFormat))
+	}
+	if err := tw.Flush(); err != nil {
+		return "", err
+	}
+	if !r.Color {
+		return buf.String(), nil
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	var out strings.Builder
+	out.WriteString(lines[0] + "\n") // header stays uncolored
+	for i, todo := range todos {
+		color := rowColor(todo)
+		if color == "" {
+			out.WriteString(lines[i+1] + "\n")
+			continue
+		}
+		out.WriteString(color + lines[i+1] + ansiReset + "\n")
+	}
+	return out.String(), nil
+}
+
+func displayPriority(todo Todo) string {
+	if todo.Priority == "" {
+		return "-"
+	}
+	return todo.Priority
+}
+
+func displayDue(todo Todo) string {
+	if todo.DueDate == nil {
+		return "-"
+	}
+	return todo.DueDate.Format(todoTxtDateFormat)
+}
+
+func displayStatus(todo Todo) string {
+	if todo.Completed {
+		return "Completed"
+	}
+	return "Incomplete"
+}
+
+func displayTags(todo Todo) string {
+	var tags []string
+	for _, p := range todo.Projects {
+		tags = append(tags, "+"+p)
+	}
+	for _, c := range todo.Contexts {
+		tags = append(tags, "@"+c)
+	}
+	if len(tags) == 0 {
+		return "-"
+	}
+	return strings.Join(tags, " ")
+}
+
+// rowColor picks the ANSI color for a table row: overdue beats due-today
+// beats completed beats low-priority, with no color for everything else.
+func rowColor(todo Todo) string {
+	if !todo.Completed && todo.DueDate != nil {
+		today := time.Now()
+		if todo.DueDate.Before(startOfDay(today)) {
+			return ansiRed
+		}
+		if isSameDay(*todo.DueDate, today) {
+			return ansiYellow
+		}
+	}
+	if todo.Completed {
+		return ansiGreen
+	}
+	if todo.Priority != "" && todo.Priority > lowPriorityThreshold {
+		return ansiDim
+	}
+	return ""
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func isSameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// isTerminal reports whether f is attached to a terminal rather than a
+// pipe or redirected file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorEnabled reports whether ANSI colors should be used: stdout must be
+// a terminal and NO_COLOR must be unset, per https://no-color.org.
+func colorEnabled() bool {
+	return isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+}
+
+// resolveRenderer picks a Renderer for the given --format value. An empty
+// format auto-detects: a table when stdout is a terminal, plain text
+// otherwise.
+func resolveRenderer(format string) (Renderer, error) {
+	switch format {
+	case "json":
+		return JSONRenderer{}, nil
+	case "table":
+		return TableRenderer{Color: colorEnabled()}, nil
+	case "plain":
+		return PlainRenderer{}, nil
+	case "":
+		if isTerminal(os.Stdout) {
+			return TableRenderer{Color: colorEnabled()}, nil
+		}
+		return PlainRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestJSONRendererEmptyResultIsEmptyArray(t *testing.T) {
+	got, err := JSONRenderer{}.Render(nil)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if got != "[]\n" {
+		t.Errorf("Render(nil) = %q, want %q", got, "[]\n")
+	}
+}
+
+func TestJSONRendererEmptySliceIsEmptyArray(t *testing.T) {
+	got, err := JSONRenderer{}.Render([]Todo{})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if got != "[]\n" {
+		t.Errorf("Render([]Todo{}) = %q, want %q", got, "[]\n")
+	}
+}
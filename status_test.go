@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusLineWarnsOnDueSoon(t *testing.T) {
+	soon := time.Now().Add(time.Hour)
+	tl := &TodoList{todos: []Todo{
+		{ID: 1, Title: "urgent", DueDate: &soon},
+	}}
+
+	got := tl.StatusLine("")
+	if !contains(got, `"state":"Warning"`) {
+		t.Errorf("StatusLine() = %q, want it to report Warning", got)
+	}
+}
+
+func TestStatusLineGoodWithNoDueSoonItems(t *testing.T) {
+	future := time.Now().Add(7 * 24 * time.Hour)
+	tl := &TodoList{todos: []Todo{
+		{ID: 1, Title: "not urgent", DueDate: &future},
+	}}
+
+	got := tl.StatusLine("")
+	if !contains(got, `"state":"Good"`) {
+		t.Errorf("StatusLine() = %q, want it to report Good", got)
+	}
+}
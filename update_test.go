@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestUpdateTodoTitleOnlyLeavesCompletionUntouched(t *testing.T) {
+	tl := &TodoList{todos: []Todo{{ID: 1, Title: "old title", Completed: true}}}
+
+	tl.UpdateTodo(1, "new title", nil)
+
+	got := tl.todos[0]
+	if got.Title != "new title" {
+		t.Errorf("Title = %q, want %q", got.Title, "new title")
+	}
+	if !got.Completed {
+		t.Errorf("Completed = false, want true (title-only update must not touch it)")
+	}
+	if got.CompletedAt != nil {
+		t.Errorf("CompletedAt = %v, want nil", got.CompletedAt)
+	}
+}
+
+func TestUpdateTodoCompletedOnlyLeavesTitleUntouched(t *testing.T) {
+	tl := &TodoList{todos: []Todo{{ID: 1, Title: "keep me", Completed: false}}}
+	done := true
+
+	tl.UpdateTodo(1, "", &done)
+
+	got := tl.todos[0]
+	if got.Title != "keep me" {
+		t.Errorf("Title = %q, want %q", got.Title, "keep me")
+	}
+	if !got.Completed {
+		t.Errorf("Completed = false, want true")
+	}
+	if got.CompletedAt == nil {
+		t.Errorf("CompletedAt = nil, want set after completing")
+	}
+}
+
+func TestUpdateTodoNilCompletedLeavesCompletedFalseUntouched(t *testing.T) {
+	tl := &TodoList{todos: []Todo{{ID: 1, Title: "old title", Completed: false}}}
+
+	tl.UpdateTodo(1, "new title", nil)
+
+	got := tl.todos[0]
+	if got.Title != "new title" {
+		t.Errorf("Title = %q, want %q", got.Title, "new title")
+	}
+	if got.Completed {
+		t.Errorf("Completed = true, want false")
+	}
+}